@@ -0,0 +1,173 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	awsv2 "github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// AccountConfig describes a single AWS account to assume a role into. When a
+// BaseConfig has no Accounts, collectors fall back to the default credential
+// chain of the identity running the exporter.
+type AccountConfig struct {
+	RoleARN     string   `yaml:"role_arn"`
+	ExternalID  string   `yaml:"external_id"`
+	SessionName string   `yaml:"session_name"`
+	Regions     []string `yaml:"regions"`
+}
+
+// ResolvedAWSConfig pairs a ready-to-use aws.Config with the account it
+// resolves to, so collectors can label every emitted metric with account_id
+// without re-calling STS on every scrape.
+type ResolvedAWSConfig struct {
+	Config    awsv2.Config
+	AccountID string
+}
+
+var accountResolveErrorsTotal = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "account_resolve_errors_total",
+		Help:      "Number of errors encountered while assuming a role or resolving an account identity, by account.",
+	},
+	[]string{"role_arn"},
+)
+
+func init() {
+	prometheus.MustRegister(accountResolveErrorsTotal)
+}
+
+// accountIDCache memoizes sts:GetCallerIdentity results keyed by role ARN (or
+// "default" for the base identity) so repeated region configs for the same
+// account don't each pay for a round trip.
+type accountIDCache struct {
+	mu  sync.Mutex
+	ids map[string]string
+}
+
+func newAccountIDCache() *accountIDCache {
+	return &accountIDCache{ids: make(map[string]string)}
+}
+
+func (c *accountIDCache) resolve(ctx context.Context, key string, cfg awsv2.Config) (string, error) {
+	c.mu.Lock()
+	if id, ok := c.ids[key]; ok {
+		c.mu.Unlock()
+		return id, nil
+	}
+	c.mu.Unlock()
+
+	out, err := sts.NewFromConfig(cfg).GetCallerIdentity(ctx, &sts.GetCallerIdentityInput{})
+	if err != nil {
+		return "", err
+	}
+	id := awsv2.ToString(out.Account)
+
+	c.mu.Lock()
+	c.ids[key] = id
+	c.mu.Unlock()
+	return id, nil
+}
+
+// unknownAccountID labels metrics for the default credential chain when
+// sts:GetCallerIdentity can't be resolved (e.g. the running role lacks
+// sts:GetCallerIdentity). Pre-dating multi-account support, single-account
+// deployments never needed that permission, so a failure here must degrade
+// rather than stop the collector from starting.
+const unknownAccountID = "unknown"
+
+// resolveAWSConfigs builds one ResolvedAWSConfig per region for every
+// configured account, assuming each account's role off the base credential
+// chain. When accounts is empty it falls back to today's single-identity
+// behavior, resolving the caller's own account_id. Failures to assume a role
+// or resolve an identity are retried with backoff and counted per account so
+// a broken role in one account doesn't stall the others.
+func resolveAWSConfigs(ctx context.Context, logger *slog.Logger, defaultRegions []string, accounts []AccountConfig) ([]ResolvedAWSConfig, error) {
+	cache := newAccountIDCache()
+
+	if len(accounts) == 0 {
+		var resolved []ResolvedAWSConfig
+		for _, region := range defaultRegions {
+			cfg, err := loadAWSConfig(ctx, region)
+			if err != nil {
+				return nil, err
+			}
+			accountID, err := resolveWithBackoff(ctx, logger, "default", func() (string, error) {
+				return cache.resolve(ctx, "default", cfg)
+			})
+			if err != nil {
+				accountResolveErrorsTotal.WithLabelValues("default").Inc()
+				logger.Warn("Could not resolve default account identity, labeling metrics as unknown", "region", region, "err", err)
+				accountID = unknownAccountID
+			}
+			resolved = append(resolved, ResolvedAWSConfig{Config: cfg, AccountID: accountID})
+		}
+		return resolved, nil
+	}
+
+	var resolved []ResolvedAWSConfig
+	for _, account := range accounts {
+		regions := account.Regions
+		if len(regions) == 0 {
+			regions = defaultRegions
+		}
+		for _, region := range regions {
+			base, err := loadAWSConfig(ctx, region)
+			if err != nil {
+				return nil, err
+			}
+			stsClient := sts.NewFromConfig(base)
+			provider := stscreds.NewAssumeRoleProvider(stsClient, account.RoleARN, func(o *stscreds.AssumeRoleOptions) {
+				if account.ExternalID != "" {
+					o.ExternalID = awsv2.String(account.ExternalID)
+				}
+				if account.SessionName != "" {
+					o.RoleSessionName = account.SessionName
+				}
+			})
+			cfg := base.Copy()
+			cfg.Credentials = awsv2.NewCredentialsCache(provider)
+
+			accountID, err := resolveWithBackoff(ctx, logger, account.RoleARN, func() (string, error) {
+				return cache.resolve(ctx, account.RoleARN, cfg)
+			})
+			if err != nil {
+				accountResolveErrorsTotal.WithLabelValues(account.RoleARN).Inc()
+				logger.Error("Could not resolve account identity", "role_arn", account.RoleARN, "region", region, "err", err)
+				continue
+			}
+			resolved = append(resolved, ResolvedAWSConfig{Config: cfg, AccountID: accountID})
+		}
+	}
+	return resolved, nil
+}
+
+// resolveWithBackoff retries a transient STS failure (e.g. a role that isn't
+// assumable yet) a few times with exponential backoff before giving up.
+func resolveWithBackoff(ctx context.Context, logger *slog.Logger, roleARN string, fn func() (string, error)) (string, error) {
+	const maxAttempts = 3
+	backoff := 500 * time.Millisecond
+	var lastErr error
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		id, err := fn()
+		if err == nil {
+			return id, nil
+		}
+		lastErr = err
+		logger.Warn("Retrying account identity resolution", "role_arn", roleARN, "attempt", attempt, "err", err)
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return "", ctx.Err()
+		}
+		backoff *= 2
+	}
+	return "", fmt.Errorf("giving up resolving account %q after %d attempts: %w", roleARN, maxAttempts, lastErr)
+}
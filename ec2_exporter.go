@@ -0,0 +1,111 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type ec2InstanceKey struct {
+	Region        string
+	AccountID     string
+	InstanceType  string
+	InstanceState string
+}
+
+// EC2Exporter describes EC2 instances across every resolved region/account
+// on its own interval and serves the cached counts to Prometheus.
+type EC2Exporter struct {
+	configs []ResolvedAWSConfig
+	logger  *slog.Logger
+	config  EC2Config
+
+	instances *prometheus.Desc
+
+	mu     sync.RWMutex
+	counts map[ec2InstanceKey]float64
+}
+
+func NewEC2Exporter(configs []ResolvedAWSConfig, logger *slog.Logger, config EC2Config) *EC2Exporter {
+	return &EC2Exporter{
+		configs: configs,
+		logger:  logger,
+		config:  config,
+		instances: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "ec2", "instances"),
+			"Number of EC2 instances, by instance type and state.",
+			[]string{"region", "account_id", "instance_type", "state"}, nil,
+		),
+		counts: make(map[ec2InstanceKey]float64),
+	}
+}
+
+func (e *EC2Exporter) Describe(ch chan<- *prometheus.Desc) {
+	ch <- e.instances
+}
+
+func (e *EC2Exporter) Collect(ch chan<- prometheus.Metric) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	for key, count := range e.counts {
+		ch <- prometheus.MustNewConstMetric(e.instances, prometheus.GaugeValue, count, key.Region, key.AccountID, key.InstanceType, key.InstanceState)
+	}
+}
+
+// CollectLoop scrapes immediately, then on every Interval until ctx is
+// cancelled (e.g. by the Manager stopping this collector on reload).
+func (e *EC2Exporter) CollectLoop(ctx context.Context) {
+	e.scrape(ctx)
+	ticker := time.NewTicker(*e.config.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			e.scrape(ctx)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (e *EC2Exporter) scrape(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, *e.config.Timeout)
+	defer cancel()
+
+	counts := make(map[ec2InstanceKey]float64)
+	for _, resolved := range e.configs {
+		client := ec2.NewFromConfig(resolved.Config)
+		var nextToken *string
+		for {
+			out, err := client.DescribeInstances(ctx, &ec2.DescribeInstancesInput{NextToken: nextToken})
+			if err != nil {
+				exporterMetrics.ScrapeErrors.WithLabelValues("ec2").Inc()
+				e.logger.Error("Could not describe EC2 instances", "region", resolved.Config.Region, "account_id", resolved.AccountID, "err", err)
+				break
+			}
+			for _, reservation := range out.Reservations {
+				for _, instance := range reservation.Instances {
+					key := ec2InstanceKey{
+						Region:        resolved.Config.Region,
+						AccountID:     resolved.AccountID,
+						InstanceType:  string(instance.InstanceType),
+						InstanceState: string(instance.State.Name),
+					}
+					counts[key]++
+				}
+			}
+			if out.NextToken == nil {
+				break
+			}
+			nextToken = out.NextToken
+		}
+	}
+
+	e.mu.Lock()
+	e.counts = counts
+	e.mu.Unlock()
+}
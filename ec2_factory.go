@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"gopkg.in/yaml.v2"
+)
+
+type EC2Config struct {
+	BaseConfig `yaml:"base,inline"`
+	Timeout    *time.Duration `yaml:"timeout"`
+	Regions    []string       `yaml:"regions"`
+}
+
+func (c *EC2Config) applyDefaults() {
+	if c.CacheTTL == nil {
+		c.CacheTTL = durationPtr(35 * time.Second)
+	}
+	if c.Interval == nil {
+		c.Interval = durationPtr(15 * time.Second)
+	}
+	if c.Timeout == nil {
+		c.Timeout = durationPtr(10 * time.Second)
+	}
+}
+
+func ec2Spec(c EC2Config) collectorSpec {
+	return collectorSpec{Enabled: c.Enabled, Regions: c.Regions, Interval: *c.Interval, CacheTTL: *c.CacheTTL, Accounts: c.Accounts}
+}
+
+type ec2Factory struct{}
+
+func (ec2Factory) Name() string { return "ec2" }
+
+func (ec2Factory) Spec(raw []byte) (collectorSpec, error) {
+	var c EC2Config
+	if err := yaml.Unmarshal(raw, &c); err != nil {
+		return collectorSpec{}, err
+	}
+	c.applyDefaults()
+	return ec2Spec(c), nil
+}
+
+func (ec2Factory) Build(ctx context.Context, logger *slog.Logger, health *HealthRegistry, raw []byte) (prometheus.Collector, Runner, collectorSpec, error) {
+	var c EC2Config
+	if err := yaml.Unmarshal(raw, &c); err != nil {
+		return nil, nil, collectorSpec{}, err
+	}
+	c.applyDefaults()
+	logger.Info("Will EC2 metrics be gathered?", "ec2-enabled", c.Enabled, "regions", c.Regions)
+	if !c.Enabled {
+		return nil, nil, ec2Spec(c), nil
+	}
+
+	configs, err := resolveAWSConfigs(ctx, logger, c.Regions, c.Accounts)
+	if err != nil {
+		return nil, nil, collectorSpec{}, err
+	}
+	registerReachabilityChecks(health, "ec2", configs)
+	exporter := NewEC2Exporter(configs, logger, c)
+	return exporter, exporter, ec2Spec(c), nil
+}
+
+func init() {
+	Register(ec2Factory{})
+}
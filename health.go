@@ -0,0 +1,252 @@
+package main
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// HealthCheck is a single named AWS reachability probe, e.g.
+// "rds-123456789012-us-east-1-reachability". Collectors register one or more
+// of these so /readyz can gate traffic on whether the exporter can actually
+// talk to AWS. A critical check keeps /readyz unhealthy until it has passed
+// once; after that it only flips /readyz to unhealthy once it has been failing
+// continuously for FailureThreshold, so a single transient error (e.g. one
+// throttled STS call) doesn't flap readiness. FailureThreshold defaults to
+// defaultFailureThreshold when zero.
+type HealthCheck struct {
+	Name             string
+	Interval         time.Duration
+	Check            func() error
+	Critical         bool
+	FailureThreshold time.Duration
+}
+
+// defaultFailureThreshold is used when a HealthCheck doesn't set its own.
+const defaultFailureThreshold = 2 * time.Minute
+
+type checkResult struct {
+	lastRun      time.Time
+	lastSuccess  time.Time
+	err          error
+	everPassed   bool
+	failingSince time.Time
+}
+
+// HealthRegistry runs registered checks on their own ticker, caches the last
+// result of each, and aggregates the outcome for /healthz and /readyz. A
+// cached result does not expire; it is only replaced by the check's next run.
+type HealthRegistry struct {
+	logger *slog.Logger
+
+	mu      sync.RWMutex
+	checks  map[string]HealthCheck
+	results map[string]checkResult
+	stops   map[string]chan struct{}
+
+	gauge *prometheus.GaugeVec
+}
+
+func NewHealthRegistry(logger *slog.Logger) *HealthRegistry {
+	return &HealthRegistry{
+		logger:  logger,
+		checks:  make(map[string]HealthCheck),
+		results: make(map[string]checkResult),
+		stops:   make(map[string]chan struct{}),
+		gauge: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "health_check",
+			Help:      "State of a named AWS reachability health check: 1 on the series whose status label (pass or fail) matches its latest result, 0 on the other.",
+		}, []string{"name", "status"}),
+	}
+}
+
+func (h *HealthRegistry) Describe(ch chan<- *prometheus.Desc) {
+	h.gauge.Describe(ch)
+}
+
+func (h *HealthRegistry) Collect(ch chan<- prometheus.Metric) {
+	h.gauge.Collect(ch)
+}
+
+// Register adds a check and starts running it on its own ticker. Call before
+// the registry is scraped or queried so /readyz doesn't briefly report ready
+// due to a missing check. Registering a check under a name that's already
+// running replaces it, stopping the old loop first.
+func (h *HealthRegistry) Register(check HealthCheck) {
+	h.mu.Lock()
+	if old, ok := h.stops[check.Name]; ok {
+		close(old)
+	}
+	stop := make(chan struct{})
+	h.checks[check.Name] = check
+	h.stops[check.Name] = stop
+	h.mu.Unlock()
+
+	go h.runLoop(check, stop)
+}
+
+// UnregisterCollector stops and removes every check owned by collectorName
+// (i.e. named collectorName+"-..."), clearing their cached results and gauge
+// values. Call this before a collector is rebuilt or removed on reload so its
+// checks don't keep running against stale regions/accounts and gating
+// /readyz forever.
+func (h *HealthRegistry) UnregisterCollector(collectorName string) {
+	prefix := collectorName + "-"
+
+	h.mu.Lock()
+	var names []string
+	for name := range h.checks {
+		if strings.HasPrefix(name, prefix) {
+			names = append(names, name)
+		}
+	}
+	for _, name := range names {
+		if stop, ok := h.stops[name]; ok {
+			close(stop)
+			delete(h.stops, name)
+		}
+		delete(h.checks, name)
+		delete(h.results, name)
+	}
+	h.mu.Unlock()
+
+	for _, name := range names {
+		h.gauge.DeleteLabelValues(name, "pass")
+		h.gauge.DeleteLabelValues(name, "fail")
+	}
+}
+
+func (h *HealthRegistry) runLoop(check HealthCheck, stop chan struct{}) {
+	h.run(check)
+	ticker := time.NewTicker(check.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			h.run(check)
+		case <-stop:
+			return
+		}
+	}
+}
+
+func (h *HealthRegistry) run(check HealthCheck) {
+	err := check.Check()
+	now := time.Now()
+
+	h.mu.Lock()
+	result := h.results[check.Name]
+	result.lastRun = now
+	result.err = err
+	if err == nil {
+		result.lastSuccess = now
+		result.everPassed = true
+		result.failingSince = time.Time{}
+	} else if result.failingSince.IsZero() {
+		result.failingSince = now
+	}
+	h.results[check.Name] = result
+	h.mu.Unlock()
+
+	h.gauge.WithLabelValues(check.Name, "pass").Set(boolToFloat(err == nil))
+	h.gauge.WithLabelValues(check.Name, "fail").Set(boolToFloat(err != nil))
+
+	if err != nil {
+		h.logger.Warn("Health check failing", "check", check.Name, "err", err)
+	}
+}
+
+func boolToFloat(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+type checkStatus struct {
+	Name        string    `json:"name"`
+	Healthy     bool      `json:"healthy"`
+	LastRun     time.Time `json:"last_run"`
+	LastSuccess time.Time `json:"last_success"`
+	Error       string    `json:"error,omitempty"`
+}
+
+// Snapshot returns the current status of every registered check.
+func (h *HealthRegistry) Snapshot() []checkStatus {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	statuses := make([]checkStatus, 0, len(h.checks))
+	for name, check := range h.checks {
+		result := h.results[name]
+		status := checkStatus{
+			Name:        name,
+			Healthy:     result.err == nil && result.everPassed,
+			LastRun:     result.lastRun,
+			LastSuccess: result.lastSuccess,
+		}
+		if result.err != nil {
+			status.Error = result.err.Error()
+		}
+		_ = check
+		statuses = append(statuses, status)
+	}
+	return statuses
+}
+
+// Ready reports whether every critical check has succeeded at least once and
+// isn't failing beyond its configurable threshold. A fresh failure
+// within the threshold window doesn't flip readiness, so one throttled AWS
+// call doesn't flap /readyz.
+func (h *HealthRegistry) Ready() bool {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	now := time.Now()
+	for name, check := range h.checks {
+		if !check.Critical {
+			continue
+		}
+		result := h.results[name]
+		if !result.everPassed {
+			return false
+		}
+		if result.err == nil {
+			continue
+		}
+		threshold := check.FailureThreshold
+		if threshold <= 0 {
+			threshold = defaultFailureThreshold
+		}
+		if !result.failingSince.IsZero() && now.Sub(result.failingSince) >= threshold {
+			return false
+		}
+	}
+	return true
+}
+
+func livenessHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("ok"))
+	}
+}
+
+func readinessHandler(registry *HealthRegistry) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		statuses := registry.Snapshot()
+		w.Header().Set("Content-Type", "application/json")
+		if !registry.Ready() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		} else {
+			w.WriteHeader(http.StatusOK)
+		}
+		json.NewEncoder(w).Encode(statuses)
+	}
+}
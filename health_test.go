@@ -0,0 +1,82 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+func newTestHealthRegistry() *HealthRegistry {
+	return NewHealthRegistry(slog.New(slog.NewTextHandler(io.Discard, nil)))
+}
+
+func TestReadyNeverRun(t *testing.T) {
+	h := newTestHealthRegistry()
+	h.checks["check"] = HealthCheck{Name: "check", Critical: true}
+
+	if h.Ready() {
+		t.Fatal("expected Ready() to be false before a critical check has ever run")
+	}
+}
+
+func TestReadyFreshFailureWithinThreshold(t *testing.T) {
+	h := newTestHealthRegistry()
+	check := HealthCheck{Name: "check", Critical: true, FailureThreshold: time.Minute}
+	h.checks[check.Name] = check
+	h.results[check.Name] = checkResult{
+		everPassed:   true,
+		err:          errors.New("boom"),
+		failingSince: time.Now(),
+	}
+
+	if !h.Ready() {
+		t.Fatal("expected Ready() to stay true for a failure within the threshold window")
+	}
+}
+
+func TestReadyFailurePastThreshold(t *testing.T) {
+	h := newTestHealthRegistry()
+	check := HealthCheck{Name: "check", Critical: true, FailureThreshold: time.Minute}
+	h.checks[check.Name] = check
+	h.results[check.Name] = checkResult{
+		everPassed:   true,
+		err:          errors.New("boom"),
+		failingSince: time.Now().Add(-2 * time.Minute),
+	}
+
+	if h.Ready() {
+		t.Fatal("expected Ready() to be false once a critical check fails past its threshold")
+	}
+}
+
+func TestReadyUsesDefaultThresholdWhenUnset(t *testing.T) {
+	h := newTestHealthRegistry()
+	check := HealthCheck{Name: "check", Critical: true}
+	h.checks[check.Name] = check
+	h.results[check.Name] = checkResult{
+		everPassed:   true,
+		err:          errors.New("boom"),
+		failingSince: time.Now().Add(-defaultFailureThreshold - time.Second),
+	}
+
+	if h.Ready() {
+		t.Fatal("expected Ready() to fall back to defaultFailureThreshold when FailureThreshold is unset")
+	}
+}
+
+func TestReadyIgnoresNonCriticalChecks(t *testing.T) {
+	h := newTestHealthRegistry()
+	check := HealthCheck{Name: "check", Critical: false}
+	h.checks[check.Name] = check
+	h.results[check.Name] = checkResult{
+		everPassed:   false,
+		err:          errors.New("boom"),
+		failingSince: time.Now().Add(-time.Hour),
+	}
+
+	if !h.Ready() {
+		t.Fatal("expected Ready() to ignore a non-critical check's failure")
+	}
+}
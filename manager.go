@@ -0,0 +1,200 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"gopkg.in/yaml.v2"
+)
+
+var (
+	configReloadFailuresTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "config_reload_failures_total",
+		Help:      "Number of times reloading the YAML config failed and the previous config was kept.",
+	})
+	lastReloadSuccessTimestamp = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "last_reload_success_timestamp_seconds",
+		Help:      "Unix timestamp of the last successful config reload.",
+	})
+)
+
+func init() {
+	prometheus.MustRegister(configReloadFailuresTotal, lastReloadSuccessTimestamp)
+}
+
+// collectorSpec is what the Manager diffs between the running config and a
+// reloaded one to decide whether a collector needs restarting. Every
+// registered factory reduces its own config struct down to this common
+// shape so the Manager never needs to know the concrete config type.
+type collectorSpec struct {
+	Enabled  bool
+	Regions  []string
+	Interval time.Duration
+	CacheTTL time.Duration
+	Accounts []AccountConfig
+}
+
+func specsEqual(a, b collectorSpec) bool {
+	return reflect.DeepEqual(a, b)
+}
+
+// runningCollector tracks a started collector so the Manager can stop it (by
+// cancelling its CollectLoop) and unregister it from Prometheus when a
+// reload changes or disables it.
+type runningCollector struct {
+	collector prometheus.Collector
+	spec      collectorSpec
+	cancel    context.CancelFunc
+}
+
+// Manager owns the set of currently running collectors and the raw config
+// they were built from, and serializes the initial start and reloads against
+// each other. Scrapes do not take its lock.
+// It has no knowledge of any individual collector beyond the registry: it
+// just hands each registered factory its own YAML subtree.
+type Manager struct {
+	logger     *slog.Logger
+	registerer prometheus.Registerer
+	health     *HealthRegistry
+	configFile string
+
+	mu      sync.RWMutex
+	raw     Config
+	running map[string]*runningCollector
+}
+
+func NewManager(logger *slog.Logger, registerer prometheus.Registerer, health *HealthRegistry, configFile string) *Manager {
+	return &Manager{
+		logger:     logger,
+		registerer: registerer,
+		health:     health,
+		configFile: configFile,
+		running:    make(map[string]*runningCollector),
+	}
+}
+
+// Start performs the initial config load and starts every enabled collector.
+func (m *Manager) Start() error {
+	raw, err := loadExporterConfiguration(m.logger, m.configFile)
+	if err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.raw = raw
+
+	for _, name := range factoryNames() {
+		subtree, err := raw.subtree(name)
+		if err != nil {
+			return err
+		}
+		m.startLocked(name, subtree)
+	}
+	return nil
+}
+
+// Reload re-reads the config file and starts/stops/restarts only the
+// collectors whose spec actually changed, leaving the rest untouched. On
+// parse failure the previously running config is kept.
+func (m *Manager) Reload(path string) error {
+	raw, err := loadExporterConfiguration(m.logger, path)
+	if err != nil {
+		configReloadFailuresTotal.Inc()
+		m.logger.Error("Config reload failed, keeping previous config", "err", err)
+		return err
+	}
+
+	subtrees := make(map[string][]byte, len(factories))
+	for _, name := range factoryNames() {
+		subtree, err := raw.subtree(name)
+		if err != nil {
+			configReloadFailuresTotal.Inc()
+			m.logger.Error("Config reload failed, keeping previous config", "collector", name, "err", err)
+			return err
+		}
+		subtrees[name] = subtree
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.raw = raw
+
+	for _, name := range factoryNames() {
+		m.reloadCollectorLocked(name, subtrees[name])
+	}
+
+	lastReloadSuccessTimestamp.SetToCurrentTime()
+	return nil
+}
+
+// reloadCollectorLocked stops and restarts a collector only if its spec
+// differs from the one it was last started with; an unchanged collector is
+// left running untouched. Callers must hold m.mu.
+func (m *Manager) reloadCollectorLocked(name string, rawSubtree []byte) {
+	spec, err := factories[name].Spec(rawSubtree)
+	if err != nil {
+		m.logger.Error("Could not evaluate collector config during reload", "collector", name, "err", err)
+		return
+	}
+	if running, ok := m.running[name]; ok && specsEqual(running.spec, spec) {
+		return
+	}
+	if _, ok := m.running[name]; !ok && !spec.Enabled {
+		return
+	}
+	m.stopLocked(name)
+	m.startLocked(name, rawSubtree)
+}
+
+func (m *Manager) startLocked(name string, rawSubtree []byte) {
+	ctx, cancel := context.WithCancel(context.Background())
+	collector, runner, spec, err := factories[name].Build(ctx, m.logger, m.health, rawSubtree)
+	if err != nil {
+		m.logger.Error("Could not start collector", "collector", name, "err", err)
+		cancel()
+		return
+	}
+	if !spec.Enabled {
+		cancel()
+		return
+	}
+	if err := m.registerer.Register(collector); err != nil {
+		m.logger.Error("Could not register collector", "collector", name, "err", err)
+		cancel()
+		return
+	}
+	go runner.CollectLoop(ctx)
+	m.running[name] = &runningCollector{collector: collector, spec: spec, cancel: cancel}
+}
+
+func (m *Manager) stopLocked(name string) {
+	running, ok := m.running[name]
+	if !ok {
+		return
+	}
+	running.cancel()
+	m.registerer.Unregister(running.collector)
+	if m.health != nil {
+		m.health.UnregisterCollector(name)
+	}
+	delete(m.running, name)
+}
+
+// Config is the raw top-level YAML document, keyed by collector name. Each
+// registered CollectorFactory is handed the bytes under its own key and owns
+// unmarshaling them into whatever concrete config struct it defines.
+type Config map[string]yaml.MapSlice
+
+func (c Config) subtree(name string) ([]byte, error) {
+	node, ok := c[name]
+	if !ok {
+		return nil, nil
+	}
+	return yaml.Marshal(node)
+}
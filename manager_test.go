@@ -0,0 +1,109 @@
+package main
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestSpecsEqual(t *testing.T) {
+	a := collectorSpec{Enabled: true, Regions: []string{"us-east-1"}, Interval: time.Minute}
+	b := collectorSpec{Enabled: true, Regions: []string{"us-east-1"}, Interval: time.Minute}
+	c := collectorSpec{Enabled: true, Regions: []string{"us-west-2"}, Interval: time.Minute}
+
+	if !specsEqual(a, b) {
+		t.Fatal("expected identical specs to be equal")
+	}
+	if specsEqual(a, c) {
+		t.Fatal("expected specs with different regions to be unequal")
+	}
+}
+
+// fakeRunner is a no-op Runner whose CollectLoop closes stopped once its
+// context is cancelled, so tests can assert on restart behavior without
+// touching AWS.
+type fakeRunner struct {
+	stopped chan struct{}
+}
+
+func (r *fakeRunner) CollectLoop(ctx context.Context) {
+	<-ctx.Done()
+	close(r.stopped)
+}
+
+// fakeFactory is a CollectorFactory that builds fakeRunners instead of
+// talking to AWS, so Manager's diff/restart logic can be tested in isolation.
+// It records every runner it builds so tests can assert a stale one was
+// actually stopped.
+type fakeFactory struct {
+	name  string
+	built []*fakeRunner
+}
+
+func (f *fakeFactory) Name() string { return f.name }
+
+func (f *fakeFactory) Spec(raw []byte) (collectorSpec, error) {
+	return collectorSpec{Enabled: true, Interval: time.Duration(len(raw))}, nil
+}
+
+func (f *fakeFactory) Build(ctx context.Context, logger *slog.Logger, health *HealthRegistry, raw []byte) (prometheus.Collector, Runner, collectorSpec, error) {
+	spec, _ := f.Spec(raw)
+	runner := &fakeRunner{stopped: make(chan struct{})}
+	f.built = append(f.built, runner)
+	return prometheus.NewGauge(prometheus.GaugeOpts{Name: "fake_" + f.name}), runner, spec, nil
+}
+
+func newTestManager(t *testing.T, factory *fakeFactory) *Manager {
+	t.Helper()
+	factories[factory.name] = factory
+	t.Cleanup(func() { delete(factories, factory.name) })
+
+	return &Manager{
+		logger:     slog.New(slog.NewTextHandler(io.Discard, nil)),
+		registerer: prometheus.NewRegistry(),
+		running:    make(map[string]*runningCollector),
+	}
+}
+
+func TestReloadCollectorLockedLeavesUnchangedSpecRunning(t *testing.T) {
+	factory := &fakeFactory{name: "fakeunchanged"}
+	m := newTestManager(t, factory)
+
+	m.startLocked(factory.name, []byte("same"))
+	running := m.running[factory.name]
+	if running == nil {
+		t.Fatal("expected collector to be running after startLocked")
+	}
+
+	m.reloadCollectorLocked(factory.name, []byte("same"))
+
+	if m.running[factory.name] != running {
+		t.Fatal("expected reloadCollectorLocked to leave an unchanged collector untouched")
+	}
+}
+
+func TestReloadCollectorLockedRestartsOnChangedSpec(t *testing.T) {
+	factory := &fakeFactory{name: "fakechanged"}
+	m := newTestManager(t, factory)
+
+	m.startLocked(factory.name, []byte("same"))
+	original := m.running[factory.name]
+
+	m.reloadCollectorLocked(factory.name, []byte("different"))
+
+	select {
+	case <-factory.built[0].stopped:
+	case <-time.After(time.Second):
+		t.Fatal("expected the stale collector's CollectLoop to be cancelled on restart")
+	}
+	if m.running[factory.name] == original {
+		t.Fatal("expected reloadCollectorLocked to replace a collector whose spec changed")
+	}
+	if !specsEqual(m.running[factory.name].spec, collectorSpec{Enabled: true, Interval: time.Duration(len("different"))}) {
+		t.Fatal("expected the restarted collector to run with the new spec")
+	}
+}
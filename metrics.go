@@ -0,0 +1,40 @@
+package main
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// ExporterMetrics tracks the exporter's own scrape health per collector so
+// operators can tell "no resources found" apart from "the scrape against AWS
+// is broken" without digging through logs. The collectors' scrapes increment
+// ScrapeErrors; none of them observes ScrapeDuration, so its histogram stays
+// empty.
+type ExporterMetrics struct {
+	ScrapeDuration *prometheus.HistogramVec
+	ScrapeErrors   *prometheus.CounterVec
+}
+
+func NewExporterMetrics() *ExporterMetrics {
+	return &ExporterMetrics{
+		ScrapeDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "scrape_duration_seconds",
+			Help:      "Time it took a collector to scrape its AWS API calls.",
+		}, []string{"collector"}),
+		ScrapeErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "scrape_errors_total",
+			Help:      "Number of scrape errors, by collector.",
+		}, []string{"collector"}),
+	}
+}
+
+func (e *ExporterMetrics) Describe(ch chan<- *prometheus.Desc) {
+	e.ScrapeDuration.Describe(ch)
+	e.ScrapeErrors.Describe(ch)
+}
+
+func (e *ExporterMetrics) Collect(ch chan<- prometheus.Metric) {
+	e.ScrapeDuration.Collect(ch)
+	e.ScrapeErrors.Collect(ch)
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	awsv2 "github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/rds"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type rdsInstanceKey struct {
+	Region    string
+	AccountID string
+	Engine    string
+	Status    string
+}
+
+// RDSExporter describes RDS instances across every resolved region/account
+// on its own interval and serves the cached counts to Prometheus, so a slow
+// DescribeDBInstances call never blocks a scrape.
+type RDSExporter struct {
+	configs []ResolvedAWSConfig
+	logger  *slog.Logger
+	config  RDSConfig
+
+	instances *prometheus.Desc
+
+	mu     sync.RWMutex
+	counts map[rdsInstanceKey]float64
+}
+
+func NewRDSExporter(configs []ResolvedAWSConfig, logger *slog.Logger, config RDSConfig) *RDSExporter {
+	return &RDSExporter{
+		configs: configs,
+		logger:  logger,
+		config:  config,
+		instances: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "rds", "instances"),
+			"Number of RDS instances, by engine and status.",
+			[]string{"region", "account_id", "engine", "status"}, nil,
+		),
+		counts: make(map[rdsInstanceKey]float64),
+	}
+}
+
+func (e *RDSExporter) Describe(ch chan<- *prometheus.Desc) {
+	ch <- e.instances
+}
+
+func (e *RDSExporter) Collect(ch chan<- prometheus.Metric) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	for key, count := range e.counts {
+		ch <- prometheus.MustNewConstMetric(e.instances, prometheus.GaugeValue, count, key.Region, key.AccountID, key.Engine, key.Status)
+	}
+}
+
+// CollectLoop scrapes immediately, then on every Interval until ctx is
+// cancelled (e.g. by the Manager stopping this collector on reload).
+func (e *RDSExporter) CollectLoop(ctx context.Context) {
+	e.scrape(ctx)
+	ticker := time.NewTicker(*e.config.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			e.scrape(ctx)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (e *RDSExporter) scrape(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, DEFAULT_TIMEOUT)
+	defer cancel()
+
+	counts := make(map[rdsInstanceKey]float64)
+	for _, resolved := range e.configs {
+		client := rds.NewFromConfig(resolved.Config)
+		var marker *string
+		for {
+			out, err := client.DescribeDBInstances(ctx, &rds.DescribeDBInstancesInput{Marker: marker})
+			if err != nil {
+				exporterMetrics.ScrapeErrors.WithLabelValues("rds").Inc()
+				e.logger.Error("Could not describe RDS instances", "region", resolved.Config.Region, "account_id", resolved.AccountID, "err", err)
+				break
+			}
+			for _, instance := range out.DBInstances {
+				key := rdsInstanceKey{
+					Region:    resolved.Config.Region,
+					AccountID: resolved.AccountID,
+					Engine:    awsv2.ToString(instance.Engine),
+					Status:    awsv2.ToString(instance.DBInstanceStatus),
+				}
+				counts[key]++
+			}
+			if out.Marker == nil {
+				break
+			}
+			marker = out.Marker
+		}
+	}
+
+	e.mu.Lock()
+	e.counts = counts
+	e.mu.Unlock()
+}
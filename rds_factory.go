@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"gopkg.in/yaml.v2"
+)
+
+type RDSConfig struct {
+	BaseConfig `yaml:"base,inline"`
+	Regions    []string `yaml:"regions"`
+}
+
+func (c *RDSConfig) applyDefaults() {
+	if c.CacheTTL == nil {
+		c.CacheTTL = durationPtr(35 * time.Second)
+	}
+	if c.Interval == nil {
+		c.Interval = durationPtr(15 * time.Second)
+	}
+}
+
+func rdsSpec(c RDSConfig) collectorSpec {
+	return collectorSpec{Enabled: c.Enabled, Regions: c.Regions, Interval: *c.Interval, CacheTTL: *c.CacheTTL, Accounts: c.Accounts}
+}
+
+type rdsFactory struct{}
+
+func (rdsFactory) Name() string { return "rds" }
+
+func (rdsFactory) Spec(raw []byte) (collectorSpec, error) {
+	var c RDSConfig
+	if err := yaml.Unmarshal(raw, &c); err != nil {
+		return collectorSpec{}, err
+	}
+	c.applyDefaults()
+	return rdsSpec(c), nil
+}
+
+func (rdsFactory) Build(ctx context.Context, logger *slog.Logger, health *HealthRegistry, raw []byte) (prometheus.Collector, Runner, collectorSpec, error) {
+	var c RDSConfig
+	if err := yaml.Unmarshal(raw, &c); err != nil {
+		return nil, nil, collectorSpec{}, err
+	}
+	c.applyDefaults()
+	logger.Info("Will RDS metrics be gathered?", "rds-enabled", c.Enabled, "regions", c.Regions)
+	if !c.Enabled {
+		return nil, nil, rdsSpec(c), nil
+	}
+
+	configs, err := resolveAWSConfigs(ctx, logger, c.Regions, c.Accounts)
+	if err != nil {
+		return nil, nil, collectorSpec{}, err
+	}
+	registerReachabilityChecks(health, "rds", configs)
+	exporter := NewRDSExporter(configs, logger, c)
+	return exporter, exporter, rdsSpec(c), nil
+}
+
+func init() {
+	Register(rdsFactory{})
+}
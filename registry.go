@@ -0,0 +1,56 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"sort"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Runner is anything the Manager can start as a background collection loop
+// and later stop by cancelling the context it was given.
+type Runner interface {
+	CollectLoop(ctx context.Context)
+}
+
+// CollectorFactory lets a collector package register itself without main.go
+// (or the Manager) knowing anything about it beyond its name: the factory
+// owns unmarshaling its own slice of the top-level YAML into whatever
+// concrete config struct it defines, resolving its own AWS configs/accounts,
+// and building the resulting collector.
+type CollectorFactory interface {
+	// Name is the top-level YAML key this factory's config is nested under,
+	// e.g. "rds", "vpc", "route53".
+	Name() string
+	// Spec unmarshals raw into this factory's own config type, applies its
+	// defaults, and reduces the result to a collectorSpec. It does no AWS
+	// calls, so the Manager can call it on every reload just to decide
+	// whether a collector's config actually changed.
+	Spec(raw []byte) (collectorSpec, error)
+	// Build does the same unmarshaling as Spec but, if enabled, also
+	// resolves AWS configs/accounts and constructs the collector and its
+	// Runner, registering any reachability checks against health. Only
+	// called when the Manager has decided to (re)start this collector.
+	Build(ctx context.Context, logger *slog.Logger, health *HealthRegistry, raw []byte) (collector prometheus.Collector, runner Runner, spec collectorSpec, err error)
+}
+
+var factories = make(map[string]CollectorFactory)
+
+// Register adds a factory to the registry. Collector packages call this
+// from an init() so `Manager.Start` picks them up without main.go editing a
+// hard-coded list of `if config.XConfig.Enabled` blocks.
+func Register(factory CollectorFactory) {
+	factories[factory.Name()] = factory
+}
+
+// factoryNames returns the registered factory names in a stable order, so
+// startup logs and reload diffs are deterministic across runs.
+func factoryNames() []string {
+	names := make([]string, 0, len(factories))
+	for name := range factories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
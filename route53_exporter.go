@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/route53"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type route53Key struct {
+	AccountID string
+}
+
+// Route53Exporter counts hosted zones per resolved account on its own
+// interval and serves the cached counts to Prometheus. Route53 is a global
+// service, so unlike the other exporters there is no per-region dimension.
+type Route53Exporter struct {
+	configs []ResolvedAWSConfig
+	logger  *slog.Logger
+	config  Route53Config
+
+	hostedZones *prometheus.Desc
+
+	mu     sync.RWMutex
+	counts map[route53Key]float64
+}
+
+func NewRoute53Exporter(configs []ResolvedAWSConfig, logger *slog.Logger, config Route53Config) *Route53Exporter {
+	return &Route53Exporter{
+		configs: configs,
+		logger:  logger,
+		config:  config,
+		hostedZones: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "route53", "hosted_zones"),
+			"Number of Route53 hosted zones.",
+			[]string{"account_id"}, nil,
+		),
+		counts: make(map[route53Key]float64),
+	}
+}
+
+func (e *Route53Exporter) Describe(ch chan<- *prometheus.Desc) {
+	ch <- e.hostedZones
+}
+
+func (e *Route53Exporter) Collect(ch chan<- prometheus.Metric) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	for key, count := range e.counts {
+		ch <- prometheus.MustNewConstMetric(e.hostedZones, prometheus.GaugeValue, count, key.AccountID)
+	}
+}
+
+// CollectLoop scrapes immediately, then on every Interval until ctx is
+// cancelled (e.g. by the Manager stopping this collector on reload).
+func (e *Route53Exporter) CollectLoop(ctx context.Context) {
+	e.scrape(ctx)
+	ticker := time.NewTicker(*e.config.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			e.scrape(ctx)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (e *Route53Exporter) scrape(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, *e.config.Timeout)
+	defer cancel()
+
+	counts := make(map[route53Key]float64)
+	for _, resolved := range e.configs {
+		client := route53.NewFromConfig(resolved.Config)
+		key := route53Key{AccountID: resolved.AccountID}
+		var marker *string
+		var total float64
+		for {
+			out, err := client.ListHostedZones(ctx, &route53.ListHostedZonesInput{Marker: marker})
+			if err != nil {
+				exporterMetrics.ScrapeErrors.WithLabelValues("route53").Inc()
+				e.logger.Error("Could not list Route53 hosted zones", "account_id", resolved.AccountID, "err", err)
+				break
+			}
+			total += float64(len(out.HostedZones))
+			if !out.IsTruncated {
+				break
+			}
+			marker = out.NextMarker
+		}
+		counts[key] = total
+	}
+
+	e.mu.Lock()
+	e.counts = counts
+	e.mu.Unlock()
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"gopkg.in/yaml.v2"
+)
+
+type Route53Config struct {
+	BaseConfig `yaml:"base,inline"`
+	Timeout    *time.Duration `yaml:"timeout"`
+	Region     string         `yaml:"region"` // Use only a single Region for now, as the current metric is global
+}
+
+func (c *Route53Config) applyDefaults() {
+	if c.CacheTTL == nil {
+		c.CacheTTL = durationPtr(35 * time.Second)
+	}
+	if c.Interval == nil {
+		c.Interval = durationPtr(15 * time.Second)
+	}
+	if c.Timeout == nil {
+		c.Timeout = durationPtr(10 * time.Second)
+	}
+}
+
+func route53Spec(c Route53Config) collectorSpec {
+	return collectorSpec{Enabled: c.Enabled, Regions: []string{c.Region}, Interval: *c.Interval, CacheTTL: *c.CacheTTL, Accounts: c.Accounts}
+}
+
+type route53Factory struct{}
+
+func (route53Factory) Name() string { return "route53" }
+
+func (route53Factory) Spec(raw []byte) (collectorSpec, error) {
+	var c Route53Config
+	if err := yaml.Unmarshal(raw, &c); err != nil {
+		return collectorSpec{}, err
+	}
+	c.applyDefaults()
+	return route53Spec(c), nil
+}
+
+func (route53Factory) Build(ctx context.Context, logger *slog.Logger, health *HealthRegistry, raw []byte) (prometheus.Collector, Runner, collectorSpec, error) {
+	var c Route53Config
+	if err := yaml.Unmarshal(raw, &c); err != nil {
+		return nil, nil, collectorSpec{}, err
+	}
+	c.applyDefaults()
+	logger.Info("Will Route53 metrics be gathered?", "route53-enabled", c.Enabled, "region", c.Region)
+	if !c.Enabled {
+		return nil, nil, route53Spec(c), nil
+	}
+
+	configs, err := resolveAWSConfigs(ctx, logger, []string{c.Region}, c.Accounts)
+	if err != nil {
+		return nil, nil, collectorSpec{}, err
+	}
+	registerReachabilityChecks(health, "route53", configs)
+	exporter := NewRoute53Exporter(configs, logger, c)
+	return exporter, exporter, route53Spec(c), nil
+}
+
+func init() {
+	Register(route53Factory{})
+}
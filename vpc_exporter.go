@@ -0,0 +1,117 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	awsv2 "github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type vpcKey struct {
+	Region    string
+	AccountID string
+	IsDefault string
+	State     string
+}
+
+// VPCExporter describes VPCs across every resolved region/account on its
+// own interval and serves the cached counts to Prometheus.
+type VPCExporter struct {
+	configs []ResolvedAWSConfig
+	logger  *slog.Logger
+	config  VPCConfig
+
+	vpcs *prometheus.Desc
+
+	mu     sync.RWMutex
+	counts map[vpcKey]float64
+}
+
+func NewVPCExporter(configs []ResolvedAWSConfig, logger *slog.Logger, config VPCConfig) *VPCExporter {
+	return &VPCExporter{
+		configs: configs,
+		logger:  logger,
+		config:  config,
+		vpcs: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "vpc", "vpcs"),
+			"Number of VPCs, by default-ness and state.",
+			[]string{"region", "account_id", "is_default", "state"}, nil,
+		),
+		counts: make(map[vpcKey]float64),
+	}
+}
+
+func (e *VPCExporter) Describe(ch chan<- *prometheus.Desc) {
+	ch <- e.vpcs
+}
+
+func (e *VPCExporter) Collect(ch chan<- prometheus.Metric) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	for key, count := range e.counts {
+		ch <- prometheus.MustNewConstMetric(e.vpcs, prometheus.GaugeValue, count, key.Region, key.AccountID, key.IsDefault, key.State)
+	}
+}
+
+// CollectLoop scrapes immediately, then on every Interval until ctx is
+// cancelled (e.g. by the Manager stopping this collector on reload).
+func (e *VPCExporter) CollectLoop(ctx context.Context) {
+	e.scrape(ctx)
+	ticker := time.NewTicker(*e.config.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			e.scrape(ctx)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (e *VPCExporter) scrape(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, *e.config.Timeout)
+	defer cancel()
+
+	counts := make(map[vpcKey]float64)
+	for _, resolved := range e.configs {
+		client := ec2.NewFromConfig(resolved.Config)
+		var nextToken *string
+		for {
+			out, err := client.DescribeVpcs(ctx, &ec2.DescribeVpcsInput{NextToken: nextToken})
+			if err != nil {
+				exporterMetrics.ScrapeErrors.WithLabelValues("vpc").Inc()
+				e.logger.Error("Could not describe VPCs", "region", resolved.Config.Region, "account_id", resolved.AccountID, "err", err)
+				break
+			}
+			for _, vpc := range out.Vpcs {
+				key := vpcKey{
+					Region:    resolved.Config.Region,
+					AccountID: resolved.AccountID,
+					IsDefault: boolLabel(awsv2.ToBool(vpc.IsDefault)),
+					State:     string(vpc.State),
+				}
+				counts[key]++
+			}
+			if out.NextToken == nil {
+				break
+			}
+			nextToken = out.NextToken
+		}
+	}
+
+	e.mu.Lock()
+	e.counts = counts
+	e.mu.Unlock()
+}
+
+func boolLabel(b bool) string {
+	if b {
+		return "true"
+	}
+	return "false"
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"gopkg.in/yaml.v2"
+)
+
+type VPCConfig struct {
+	BaseConfig `yaml:"base,inline"`
+	Timeout    *time.Duration `yaml:"timeout"`
+	Regions    []string       `yaml:"regions"`
+}
+
+func (c *VPCConfig) applyDefaults() {
+	if c.CacheTTL == nil {
+		c.CacheTTL = durationPtr(35 * time.Second)
+	}
+	if c.Interval == nil {
+		c.Interval = durationPtr(15 * time.Second)
+	}
+	if c.Timeout == nil {
+		c.Timeout = durationPtr(10 * time.Second)
+	}
+}
+
+func vpcSpec(c VPCConfig) collectorSpec {
+	return collectorSpec{Enabled: c.Enabled, Regions: c.Regions, Interval: *c.Interval, CacheTTL: *c.CacheTTL, Accounts: c.Accounts}
+}
+
+type vpcFactory struct{}
+
+func (vpcFactory) Name() string { return "vpc" }
+
+func (vpcFactory) Spec(raw []byte) (collectorSpec, error) {
+	var c VPCConfig
+	if err := yaml.Unmarshal(raw, &c); err != nil {
+		return collectorSpec{}, err
+	}
+	c.applyDefaults()
+	return vpcSpec(c), nil
+}
+
+func (vpcFactory) Build(ctx context.Context, logger *slog.Logger, health *HealthRegistry, raw []byte) (prometheus.Collector, Runner, collectorSpec, error) {
+	var c VPCConfig
+	if err := yaml.Unmarshal(raw, &c); err != nil {
+		return nil, nil, collectorSpec{}, err
+	}
+	c.applyDefaults()
+	logger.Info("Will VPC metrics be gathered?", "vpc-enabled", c.Enabled, "regions", c.Regions)
+	if !c.Enabled {
+		return nil, nil, vpcSpec(c), nil
+	}
+
+	configs, err := resolveAWSConfigs(ctx, logger, c.Regions, c.Accounts)
+	if err != nil {
+		return nil, nil, collectorSpec{}, err
+	}
+	registerReachabilityChecks(health, "vpc", configs)
+	exporter := NewVPCExporter(configs, logger, c)
+	return exporter, exporter, vpcSpec(c), nil
+}
+
+func init() {
+	Register(vpcFactory{})
+}